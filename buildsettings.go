@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// buildRequirements describes the build settings a project expects its
+// tool binaries to have been built with, sourced from a .gobincache.yaml
+// file and/or the --require-* flags (which are merged on top of the file).
+type buildRequirements struct {
+	Tags     []string `yaml:"require_tag"`
+	Trimpath bool     `yaml:"require_trimpath"`
+	GOOS     string   `yaml:"require_goos"`
+	GOARCH   string   `yaml:"require_goarch"`
+}
+
+// loadBuildRequirements reads path as a .gobincache.yaml file. A missing
+// file is not an error; it yields an empty (unconstrained) set of
+// requirements.
+func loadBuildRequirements(path string) (buildRequirements, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return buildRequirements{}, nil
+		}
+
+		return buildRequirements{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var req buildRequirements
+	if err := yaml.Unmarshal(b, &req); err != nil {
+		return buildRequirements{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return req, nil
+}
+
+// settingsMismatch reports the first build-setting requirement that info
+// does not satisfy, or "" if it satisfies them all.
+func settingsMismatch(info *debug.BuildInfo, req buildRequirements) string {
+	if req.Trimpath && buildSetting(info, "-trimpath") != "true" {
+		return "built without -trimpath"
+	}
+
+	tags := buildSetting(info, "-tags")
+	for _, tag := range req.Tags {
+		if !hasTag(tags, tag) {
+			return fmt.Sprintf("missing build tag %q", tag)
+		}
+	}
+
+	if req.GOOS != "" {
+		if v := buildSetting(info, "GOOS"); v != req.GOOS {
+			return fmt.Sprintf("built for GOOS=%s, want %s", v, req.GOOS)
+		}
+	}
+
+	if req.GOARCH != "" {
+		if v := buildSetting(info, "GOARCH"); v != req.GOARCH {
+			return fmt.Sprintf("built for GOARCH=%s, want %s", v, req.GOARCH)
+		}
+	}
+
+	return ""
+}
+
+func hasTag(tagsSetting, tag string) bool {
+	for _, t := range strings.Split(tagsSetting, ",") {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func buildSetting(info *debug.BuildInfo, key string) string {
+	for _, s := range info.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+
+	return ""
+}
+
+// replacedLocally reports whether modPath is replaced in gomod by a local
+// filesystem path (a "replace" directive with no version on the right-hand
+// side), the case Go's tooling uses for "=> ../local" replacements.
+func replacedLocally(gomod *modfile.File, modPath string) bool {
+	for _, r := range gomod.Replace {
+		if r.Old.Path == modPath && r.New.Version == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// vcsModified reports whether info records that it was built from a dirty
+// VCS checkout (the "vcs.modified" build setting).
+func vcsModified(info *debug.BuildInfo) bool {
+	return buildSetting(info, "vcs.modified") == "true"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+func buildInfoWithSettings(settings map[string]string) *debug.BuildInfo {
+	info := &debug.BuildInfo{}
+	for k, v := range settings {
+		info.Settings = append(info.Settings, debug.BuildSetting{Key: k, Value: v})
+	}
+
+	return info
+}
+
+func TestSettingsMismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		req      buildRequirements
+		want     string // "" means no mismatch
+	}{
+		{
+			name:     "trimpath required and present",
+			settings: map[string]string{"-trimpath": "true"},
+			req:      buildRequirements{Trimpath: true},
+			want:     "",
+		},
+		{
+			name:     "trimpath required but missing",
+			settings: map[string]string{},
+			req:      buildRequirements{Trimpath: true},
+			want:     "built without -trimpath",
+		},
+		{
+			name:     "required tag present among several",
+			settings: map[string]string{"-tags": "foo,bar"},
+			req:      buildRequirements{Tags: []string{"bar"}},
+			want:     "",
+		},
+		{
+			name:     "required tag missing",
+			settings: map[string]string{"-tags": "foo"},
+			req:      buildRequirements{Tags: []string{"bar"}},
+			want:     `missing build tag "bar"`,
+		},
+		{
+			name:     "goos mismatch",
+			settings: map[string]string{"GOOS": "linux"},
+			req:      buildRequirements{GOOS: "darwin"},
+			want:     "built for GOOS=linux, want darwin",
+		},
+		{
+			name:     "goarch mismatch",
+			settings: map[string]string{"GOARCH": "amd64"},
+			req:      buildRequirements{GOARCH: "arm64"},
+			want:     "built for GOARCH=amd64, want arm64",
+		},
+		{
+			name:     "no requirements",
+			settings: map[string]string{},
+			req:      buildRequirements{},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settingsMismatch(buildInfoWithSettings(tt.settings), tt.req)
+			if got != tt.want {
+				t.Fatalf("settingsMismatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVCSModified(t *testing.T) {
+	if !vcsModified(buildInfoWithSettings(map[string]string{"vcs.modified": "true"})) {
+		t.Fatalf("vcsModified() = false, want true")
+	}
+	if vcsModified(buildInfoWithSettings(map[string]string{"vcs.modified": "false"})) {
+		t.Fatalf("vcsModified() = true, want false")
+	}
+	if vcsModified(buildInfoWithSettings(nil)) {
+		t.Fatalf("vcsModified() = true, want false when unset")
+	}
+}
+
+// TestReplacedLocallyChecksGivenModfile guards against the chunk0-6 bug
+// where the dirty-local-replace check always consulted the root go.mod even
+// when the match came from a --tools-mod file: replacedLocally must report
+// true only for the modfile that actually holds the replace directive.
+func TestReplacedLocallyChecksGivenModfile(t *testing.T) {
+	rootGomod := &modfile.File{}
+	toolsGomod := &modfile.File{
+		Replace: []*modfile.Replace{
+			{
+				Old: module.Version{Path: "example.com/foo"},
+				New: module.Version{Path: "../local-foo"},
+			},
+		},
+	}
+
+	if replacedLocally(rootGomod, "example.com/foo") {
+		t.Fatalf("replacedLocally(rootGomod, ...) = true, want false: root go.mod has no matching replace")
+	}
+	if !replacedLocally(toolsGomod, "example.com/foo") {
+		t.Fatalf("replacedLocally(toolsGomod, ...) = false, want true: tools go.mod holds the replace")
+	}
+}
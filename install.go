@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// installCmd builds the "install" subcommand, which runs "go install" for a
+// binary that requiresInstall reports as stale.
+func installCmd() *cobra.Command {
+	var (
+		opts       checkOptions
+		toolsFile  string
+		importPath string
+		gobin      string
+		dryRun     bool
+	)
+
+	c := &cobra.Command{
+		Use:   "install [path to Go binary]",
+		Short: "Installs a binary via \"go install\" if it requires updating.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binPath := args[0]
+
+			needsInstall, err := requiresInstall(binPath, opts)
+			if err != nil {
+				return err
+			}
+			if !needsInstall {
+				return nil
+			}
+
+			gomod, err := readModFile("go.mod")
+			if err != nil {
+				return err
+			}
+
+			path := importPath
+			if path == "" {
+				path, err = toolImportPath(toolsFile, binPath)
+				if err != nil {
+					return fmt.Errorf("resolving import path for %s: %w", binPath, err)
+				}
+			}
+
+			sourceGomod := gomod
+			mod := versionFromGoMod(gomod, debug.Module{Path: path}, path)
+			if mod == nil && opts.toolsModPath != "" {
+				toolsGomod, err := readModFile(opts.toolsModPath)
+				if err != nil {
+					return err
+				}
+
+				if m := versionFromGoMod(toolsGomod, debug.Module{Path: path}, path); m != nil {
+					mod, sourceGomod = m, toolsGomod
+				}
+			}
+			if mod == nil {
+				return fmt.Errorf("%s: no matching require in go.mod", path)
+			}
+
+			if excluded(sourceGomod, *mod) {
+				return fmt.Errorf("%s@%s is excluded in go.mod, refusing to install", mod.Path, mod.Version)
+			}
+
+			effective, localReplace := resolvedVersion(sourceGomod, *mod)
+			if localReplace != nil {
+				return fmt.Errorf("%s is replaced locally in go.mod (=> %s); install it directly from that path instead of via \"go install\"", mod.Path, localReplace.New.Path)
+			}
+
+			target := fmt.Sprintf("%s@%s", path, effective.Version)
+
+			goInstall := exec.Command("go", "install", target)
+			goInstall.Env = os.Environ()
+			if gobin != "" {
+				goInstall.Env = append(goInstall.Env, "GOBIN="+gobin)
+			}
+			goInstall.Stdout = cmd.OutOrStdout()
+			goInstall.Stderr = cmd.ErrOrStderr()
+
+			if dryRun {
+				fmt.Fprintln(cmd.OutOrStdout(), strings.Join(goInstall.Args, " "))
+				return nil
+			}
+
+			if err := goInstall.Run(); err != nil {
+				return fmt.Errorf("go install %s: %w", target, err)
+			}
+
+			return nil
+		},
+	}
+
+	bindStalenessFlags(c.Flags(), &opts)
+	c.Flags().StringVar(&toolsFile, "tools-file", "tools.go", "path to the tools.go file listing blank tool imports")
+	c.Flags().StringVar(&importPath, "import-path", "", "import path of the tool to install, overrides resolution via --tools-file")
+	c.Flags().StringVar(&gobin, "gobin", "", "GOBIN directory to install into (defaults to the environment's GOBIN/GOPATH/bin)")
+	c.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "print the \"go install\" command instead of running it")
+
+	return c
+}
+
+// toolImportPath scans toolsFile for blank tool imports (the "tools.go"
+// pattern) and returns the first one whose last import path element matches
+// the base name of binPath.
+func toolImportPath(toolsFile, binPath string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, toolsFile, nil, parser.ImportsOnly)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s: %w", toolsFile, err)
+	}
+
+	name := filepath.Base(binPath)
+
+	var candidates []string
+	for _, imp := range f.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+		candidates = append(candidates, path)
+		if filepath.Base(path) == name {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("no import in %s matches binary name %q (candidates: %s)", toolsFile, name, strings.Join(candidates, ", "))
+}
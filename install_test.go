@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToolImportPath(t *testing.T) {
+	toolsFile := filepath.Join(t.TempDir(), "tools.go")
+	content := `//go:build tools
+
+package tools
+
+import (
+	_ "example.com/foo/cmd/foo"
+	_ "example.com/bar/cmd/baz"
+)
+`
+	if err := os.WriteFile(toolsFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("matches by binary base name", func(t *testing.T) {
+		got, err := toolImportPath(toolsFile, "/usr/local/bin/baz")
+		if err != nil {
+			t.Fatalf("toolImportPath() error = %v", err)
+		}
+		if got != "example.com/bar/cmd/baz" {
+			t.Fatalf("toolImportPath() = %q, want example.com/bar/cmd/baz", got)
+		}
+	})
+
+	t.Run("no import matches the binary name", func(t *testing.T) {
+		if _, err := toolImportPath(toolsFile, "/usr/local/bin/qux"); err == nil {
+			t.Fatal("toolImportPath() error = nil, want error for unmatched binary name")
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"debug/buildinfo"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
@@ -41,82 +41,96 @@ func (e *exitCodeError) Unwrap() error {
 	return e.err
 }
 
-const long = `gobincache determines whether a Go binary is up-to-date relative to its module
-in your go.mod.
+const long = `gobincache determines whether one or more Go binaries are up-to-date relative
+to their modules in your go.mod.
 
 It assumes the use of a "tools.go" approach to versioning binaries in your
 project.
 
-The command will return an exit code of 0 when the binary currently installed
-is up-to-date. It will return an exit code of 2 when it is either not present
-or requires updating via "go install".
+The command will return an exit code of 0 when every binary currently
+installed is up-to-date. It will return an exit code of 2 when any binary is
+either not present or requires updating via "go install".
 
 Any other error will cause this command to exit with a code of 1 (e.g. failing
-to parse to go.mod file).
+to parse the go.mod file). Pass "--format=json" to get a machine-readable
+status per binary instead of a plain-text summary, for use in Makefiles, CI,
+or editor tooling.
+
+With "--check-upgrades", binaries that are otherwise up-to-date are also
+checked against the module proxy for a newer released version, returning an
+exit code of 3 when one is available.
+
+A ".gobincache.yaml" file (or the "--require-*" flags, which are merged on
+top of it) can declare build settings a binary is expected to have, such as
+"-trimpath", specific "-tags", or a target GOOS/GOARCH; a binary built
+without them is reported "stale-settings". A binary replaced locally in
+go.mod ("replace foo => ../foo") built from a dirty VCS checkout
+(vcs.modified=true) is reported "stale-settings" too.
 `
 
 // cmd builds the root *cobra.Command hierarchy.
 func cmd() *cobra.Command {
+	var opts checkOptions
+
 	c := &cobra.Command{
-		Use:           "gobincache [path to Go binary]",
+		Use:           "gobincache [path to Go binary]...",
 		Short:         "Determines whether a Go binary requires updating, relative to it's version in the go.mod.",
 		Long:          long,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		Args:          cobra.ExactArgs(1),
+		Args:          cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			needsInstall, err := requiresInstall(args[0])
-			if err != nil {
-				return err
-			}
-			if needsInstall {
-				return &exitCodeError{code: 2, err: fmt.Errorf("binary requires install")}
-			}
-
-			return nil
+			return runCheck(cmd, args, opts)
 		},
 	}
 
+	bindStalenessFlags(c.Flags(), &opts)
+	c.Flags().StringVar(&opts.format, "format", "text", `output format, one of "text" or "json"`)
+	c.Flags().BoolVar(&opts.checkUpgrades, "check-upgrades", false, "also query the module proxy for a newer version of up-to-date binaries (exit code 3 if one is found)")
+
+	c.AddCommand(installCmd())
+
 	return c
 }
 
-func requiresInstall(binPath string) (bool, error) {
-	b, err := os.ReadFile("go.mod")
-	if err != nil {
-		return false, fmt.Errorf("reading modfile: %w", err)
-	}
+// bindStalenessFlags registers the flags that affect whether a binary is
+// considered stale (independent of output --format or --check-upgrades),
+// shared between the root "check" command and "install" so both honor the
+// same tools-mod/build-settings overrides.
+func bindStalenessFlags(fs *pflag.FlagSet, opts *checkOptions) {
+	fs.StringVar(&opts.toolsModPath, "tools-mod", "", "path to a tools/go.mod to also consult when resolving a pinned version (classic tools.go-in-subdir layout)")
+	fs.StringVar(&opts.requirementsPath, "config", ".gobincache.yaml", "path to a gobincache config file declaring required build settings")
+	fs.StringSliceVar(&opts.requireTags, "require-tag", nil, "require the binary was built with this -tags value (may be repeated)")
+	fs.BoolVar(&opts.requireTrimpath, "require-trimpath", false, "require the binary was built with -trimpath")
+	fs.StringVar(&opts.requireGOOS, "require-goos", "", "require the binary was built for this GOOS")
+	fs.StringVar(&opts.requireGOARCH, "require-goarch", "", "require the binary was built for this GOARCH")
+}
 
-	gomod, err := modfile.Parse("", b, nil)
-	if err != nil {
-		return false, fmt.Errorf("parsing modfile: %w", err)
+// requiresInstall reports whether the binary at binPath is missing or out of
+// date relative to the pinned version in go.mod (and, per opts, any
+// tools modfile or required build settings).
+func requiresInstall(binPath string, opts checkOptions) (bool, error) {
+	status := checkBinary(binPath, opts)
+	if status.Status == statusError {
+		return false, status.err
 	}
 
-	info, err := buildinfo.ReadFile(binPath)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return true, nil
-		}
-
-		return false, fmt.Errorf("reading binary buildinfo (%s): %w", binPath, err)
-	}
+	return status.Status != statusUpToDate, nil
+}
 
-	goUpdate, err := needsUpdateForGo(gomod, info)
+// readModFile reads and parses the go.mod file at path.
+func readModFile(path string) (*modfile.File, error) {
+	b, err := os.ReadFile(path)
 	if err != nil {
-		return false, err
-	}
-	if goUpdate {
-		return true, nil
+		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
 
-	bin := info.Main
-	mod := versionFromGoMod(gomod, bin)
-	// We didn't find a match between the modfile and the binary. Can happen if
-	// a binary has changed import paths.
-	if mod == nil {
-		return true, nil
+	gomod, err := modfile.Parse(path, b, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
 
-	return bin.Version != mod.Version, nil
+	return gomod, nil
 }
 
 func needsUpdateForGo(gomod *modfile.File, info *debug.BuildInfo) (bool, error) {
@@ -137,12 +151,57 @@ func needsUpdateForGo(gomod *modfile.File, info *debug.BuildInfo) (bool, error)
 	return false, nil
 }
 
-func versionFromGoMod(gomod *modfile.File, binaryModule debug.Module) *module.Version {
+// versionFromGoMod resolves the module+version gobincache expects
+// binaryModule to have been built from. binaryModule is the binary's main
+// module (debug.BuildInfo.Main); cmdPath is the binary's own import path
+// (debug.BuildInfo.Path), which is what a "tool" directive (Go 1.24+) names
+// and may be a subpackage of binaryModule. versionFromGoMod first looks for
+// an exact "require" match on the module itself, then for a "tool" entry
+// naming cmdPath (resolved to the longest "require" module path that
+// prefixes cmdPath), and finally for the longest "require" module path that
+// prefixes binaryModule.Path, so tools installed from a subpackage of a
+// required module are still matched.
+func versionFromGoMod(gomod *modfile.File, binaryModule debug.Module, cmdPath string) *module.Version {
+	if v := exactRequireMatch(gomod, binaryModule.Path); v != nil {
+		return v
+	}
+
+	for _, tool := range gomod.Tool {
+		if tool.Path != cmdPath {
+			continue
+		}
+
+		return longestRequirePrefixMatch(gomod, tool.Path)
+	}
+
+	return longestRequirePrefixMatch(gomod, binaryModule.Path)
+}
+
+func exactRequireMatch(gomod *modfile.File, path string) *module.Version {
 	for _, required := range gomod.Require {
-		if required.Mod.Path == binaryModule.Path {
+		if required.Mod.Path == path {
 			return &required.Mod
 		}
 	}
 
 	return nil
 }
+
+// longestRequirePrefixMatch returns the Require entry whose module path is
+// the longest prefix of path, for tools installed from a subpackage of their
+// module.
+func longestRequirePrefixMatch(gomod *modfile.File, path string) *module.Version {
+	var best *module.Version
+	for _, required := range gomod.Require {
+		modPath := required.Mod.Path
+		if !strings.HasPrefix(path, modPath+"/") {
+			continue
+		}
+
+		if best == nil || len(modPath) > len(best.Path) {
+			best = &required.Mod
+		}
+	}
+
+	return best
+}
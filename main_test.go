@@ -0,0 +1,107 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+func TestVersionFromGoMod(t *testing.T) {
+	// "example.com/direct" is a single-binary module: its go.mod's module
+	// path and its main package's import path are the same string.
+	//
+	// "golang.org/x/tools" required here models a tool installed from a
+	// *nested* module (its own go.mod rooted at ".../cmd"), so the outer
+	// go.mod only knows it as a "tool" entry naming the command's full
+	// import path, not as a "require" on that nested module.
+	gomod := &modfile.File{
+		Require: []*modfile.Require{
+			{Mod: module.Version{Path: "example.com/direct", Version: "v1.2.3"}},
+			{Mod: module.Version{Path: "golang.org/x/tools", Version: "v0.9.0"}},
+			{Mod: module.Version{Path: "example.com/shared", Version: "v0.5.0"}},
+		},
+		Tool: []*modfile.Tool{
+			{Path: "golang.org/x/tools/cmd/stringer"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		modulePath string // debug.BuildInfo.Main.Path
+		cmdPath    string // debug.BuildInfo.Path
+		want       string // expected resolved version, "" for no match
+	}{
+		{
+			name:       "exact require match",
+			modulePath: "example.com/direct",
+			cmdPath:    "example.com/direct",
+			want:       "v1.2.3",
+		},
+		{
+			name:       "tool directive matched by the command's import path, not its module",
+			modulePath: "golang.org/x/tools/cmd",
+			cmdPath:    "golang.org/x/tools/cmd/stringer",
+			want:       "v0.9.0",
+		},
+		{
+			// No "tool" directive here: this models a nested module
+			// installed from one of its own subpackages, without an
+			// explicit tool pin, so the match can only come from the
+			// longest "require" prefix of the command's own module path.
+			name:       "command's module is an unlisted subpackage of a required module",
+			modulePath: "example.com/shared/cmd",
+			cmdPath:    "example.com/shared/cmd/thing",
+			want:       "v0.5.0",
+		},
+		{
+			name:       "no match for an unrelated module",
+			modulePath: "example.com/unrelated",
+			cmdPath:    "example.com/unrelated",
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := versionFromGoMod(gomod, debug.Module{Path: tt.modulePath}, tt.cmdPath)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("versionFromGoMod() = %+v, want nil", got)
+				}
+				return
+			}
+
+			if got == nil || got.Version != tt.want {
+				t.Fatalf("versionFromGoMod() = %+v, want version %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeedsUpdateForGo(t *testing.T) {
+	gomod := &modfile.File{Go: &modfile.Go{Version: "1.22"}}
+
+	tests := []struct {
+		name       string
+		binGo      string
+		wantUpdate bool
+	}{
+		{name: "binary older than go.mod", binGo: "go1.21.0", wantUpdate: true},
+		{name: "binary matches go.mod", binGo: "go1.22.0", wantUpdate: false},
+		{name: "binary newer than go.mod", binGo: "go1.23.0", wantUpdate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := needsUpdateForGo(gomod, &debug.BuildInfo{GoVersion: tt.binGo})
+			if err != nil {
+				t.Fatalf("needsUpdateForGo() error = %v", err)
+			}
+			if got != tt.wantUpdate {
+				t.Fatalf("needsUpdateForGo() = %v, want %v", got, tt.wantUpdate)
+			}
+		})
+	}
+}
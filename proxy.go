@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// proxyClient is a small HTTP client for the Go module proxy protocol
+// (https://go.dev/ref/mod#goproxy-protocol). It only implements the two
+// endpoints gobincache needs: "@latest" and "@v/<version>.mod".
+type proxyClient struct {
+	httpClient *http.Client
+}
+
+func newProxyClient() *proxyClient {
+	return &proxyClient{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// latestInfo mirrors the JSON object returned by the proxy's "@latest"
+// endpoint.
+type latestInfo struct {
+	Version string
+	Time    string
+}
+
+// latestVersion queries GOPROXY for the latest available version of modPath,
+// honoring GOPRIVATE (modules matching it are skipped rather than sent to a
+// public proxy) and the GOPROXY list/fallback syntax. It only fetches the
+// "@latest" info, never the full module zip.
+func (c *proxyClient) latestVersion(modPath string) (*module.Version, error) {
+	if isPrivateModule(modPath) {
+		return nil, fmt.Errorf("%s matches GOPRIVATE, not querying a public proxy", modPath)
+	}
+
+	escaped, err := module.EscapePath(modPath)
+	if err != nil {
+		return nil, fmt.Errorf("escaping module path %s: %w", modPath, err)
+	}
+
+	var lastErr error
+	for _, base := range proxyList() {
+		switch base {
+		case "off":
+			return nil, fmt.Errorf("GOPROXY=off: module proxy disabled")
+		case "direct":
+			lastErr = fmt.Errorf("GOPROXY=direct: direct VCS resolution is not supported")
+			continue
+		}
+
+		info, err := c.fetchLatest(base, escaped)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return &module.Version{Path: modPath, Version: info.Version}, nil
+	}
+
+	return nil, fmt.Errorf("querying module proxy for %s: %w", modPath, lastErr)
+}
+
+// fakeGoMod reports whether the go.mod published for modPath@version is a
+// "fake" one synthesized by the proxy for a pre-module tag or pseudo-version
+// (a bare "module" directive with no "go" directive), per
+// https://go.dev/ref/mod#non-module-compat.
+func (c *proxyClient) fakeGoMod(modPath, version string) (bool, error) {
+	escaped, err := module.EscapePath(modPath)
+	if err != nil {
+		return false, fmt.Errorf("escaping module path %s: %w", modPath, err)
+	}
+
+	var lastErr error
+	for _, base := range proxyList() {
+		if base == "off" || base == "direct" {
+			continue
+		}
+
+		b, err := c.get(fmt.Sprintf("%s/%s/@v/%s.mod", strings.TrimSuffix(base, "/"), escaped, version))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		gomod, err := modfile.ParseLax(escaped+"@"+version+"/go.mod", b, nil)
+		if err != nil {
+			return false, fmt.Errorf("parsing go.mod for %s@%s: %w", modPath, version, err)
+		}
+
+		return gomod.Go == nil, nil
+	}
+
+	return false, fmt.Errorf("fetching go.mod for %s@%s: %w", modPath, version, lastErr)
+}
+
+func (c *proxyClient) fetchLatest(base, escapedPath string) (*latestInfo, error) {
+	b, err := c.get(strings.TrimSuffix(base, "/") + "/" + escapedPath + "/@latest")
+	if err != nil {
+		return nil, err
+	}
+
+	var info latestInfo
+	if err := json.Unmarshal(b, &info); err != nil {
+		return nil, fmt.Errorf("decoding @latest response: %w", err)
+	}
+
+	return &info, nil
+}
+
+func (c *proxyClient) get(url string) ([]byte, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// checkUpgrade queries GOPROXY for the latest version of modPath and reports
+// whether it is newer than installedVersion. When the proxy's go.mod for
+// that version is a "fake" one (module.SplitPathVersion + fakeGoMod), a
+// major version 2+ without a "/vN" module path suffix is reported
+// "+incompatible", matching `go list -m -u`'s handling of pre-module tags.
+func checkUpgrade(modPath, installedVersion string) (available bool, latest string, err error) {
+	client := newProxyClient()
+
+	latestMod, err := client.latestVersion(modPath)
+	if err != nil {
+		return false, "", err
+	}
+
+	if fake, err := client.fakeGoMod(modPath, latestMod.Version); err == nil && fake {
+		if _, pathMajor, ok := module.SplitPathVersion(modPath); ok && pathMajor == "" &&
+			semver.Compare(semver.Major(latestMod.Version), "v1") > 0 &&
+			!strings.HasSuffix(latestMod.Version, "+incompatible") {
+			latestMod.Version += "+incompatible"
+		}
+	}
+
+	if semver.Compare(latestMod.Version, installedVersion) > 0 {
+		return true, latestMod.Version, nil
+	}
+
+	return false, "", nil
+}
+
+// proxyList parses GOPROXY into its ordered, comma/pipe-separated list of
+// proxy URLs (and the "off"/"direct" keywords), defaulting to
+// proxy.golang.org when unset, matching `go env GOPROXY`'s default.
+func proxyList() []string {
+	goproxy := os.Getenv("GOPROXY")
+	if goproxy == "" {
+		goproxy = "https://proxy.golang.org,direct"
+	}
+
+	// The GOPROXY grammar distinguishes "," (fall back only on 404/410) from
+	// "|" (fall back on any error); gobincache isn't installing anything, so
+	// it always falls back on any error and only needs the ordered list.
+	goproxy = strings.ReplaceAll(goproxy, "|", ",")
+
+	var list []string
+	for _, entry := range strings.Split(goproxy, ",") {
+		if entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return list
+}
+
+// isPrivateModule reports whether modPath matches one of GOPRIVATE's
+// comma-separated glob patterns.
+func isPrivateModule(modPath string) bool {
+	for _, pattern := range strings.Split(os.Getenv("GOPRIVATE"), ",") {
+		if pattern == "" {
+			continue
+		}
+
+		if ok, err := path.Match(pattern, modPath); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
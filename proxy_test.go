@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProxyList(t *testing.T) {
+	tests := []struct {
+		name    string
+		goproxy string
+		want    []string
+	}{
+		{
+			name:    "unset defaults to proxy.golang.org then direct",
+			goproxy: "",
+			want:    []string{"https://proxy.golang.org", "direct"},
+		},
+		{
+			name:    "comma separated list preserved in order",
+			goproxy: "https://a.example.com,https://b.example.com",
+			want:    []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			name:    "pipe fallback flattened to the same ordered list",
+			goproxy: "https://a.example.com|https://b.example.com",
+			want:    []string{"https://a.example.com", "https://b.example.com"},
+		},
+		{
+			name:    "off is passed through",
+			goproxy: "off",
+			want:    []string{"off"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOPROXY", tt.goproxy)
+
+			got := proxyList()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("proxyList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrivateModule(t *testing.T) {
+	t.Setenv("GOPRIVATE", "example.com/internal/*,github.com/acme/private")
+
+	tests := []struct {
+		modPath string
+		want    bool
+	}{
+		{modPath: "example.com/internal/tool", want: true},
+		{modPath: "github.com/acme/private", want: true},
+		{modPath: "github.com/acme/public", want: false},
+		{modPath: "example.com/other", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isPrivateModule(tt.modPath); got != tt.want {
+			t.Errorf("isPrivateModule(%q) = %v, want %v", tt.modPath, got, tt.want)
+		}
+	}
+}
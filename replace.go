@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// replacementFor returns the "replace" directive that applies to path at
+// version, mirroring modload.Replacement: an exact-version replace ("path v
+// => ...") takes priority over a wildcard one ("path => ...") that applies
+// to every version of path.
+func replacementFor(gomod *modfile.File, path, version string) *modfile.Replace {
+	var wildcard *modfile.Replace
+	for _, r := range gomod.Replace {
+		if r.Old.Path != path {
+			continue
+		}
+		if r.Old.Version == version {
+			return r
+		}
+		if r.Old.Version == "" {
+			wildcard = r
+		}
+	}
+
+	return wildcard
+}
+
+// resolvedVersion applies any "replace" directive covering mod, returning
+// the effective module+version gobincache expects the binary to have been
+// built from. When the replacement targets a local filesystem path rather
+// than another module (New.Version == ""), the directive itself is also
+// returned so the caller can compare it against the binary by mtime instead
+// of by version.
+func resolvedVersion(gomod *modfile.File, mod module.Version) (module.Version, *modfile.Replace) {
+	r := replacementFor(gomod, mod.Path, mod.Version)
+	if r == nil {
+		return mod, nil
+	}
+	if r.New.Version == "" {
+		return r.New, r
+	}
+
+	return r.New, nil
+}
+
+// excluded reports whether gomod's "exclude" directives name mod.
+func excluded(gomod *modfile.File, mod module.Version) bool {
+	for _, e := range gomod.Exclude {
+		if e.Mod == mod {
+			return true
+		}
+	}
+
+	return false
+}
+
+// localReplaceStale reports whether a local-path replace target (r.New.Path,
+// resolved relative to baseDir when not absolute) has a go.mod modified more
+// recently than binPath, i.e. the replacement's source changed since the
+// binary was last built.
+func localReplaceStale(r *modfile.Replace, baseDir, binPath string) (bool, error) {
+	dir := r.New.Path
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+
+	binInfo, err := os.Stat(binPath)
+	if err != nil {
+		return false, fmt.Errorf("stating %s: %w", binPath, err)
+	}
+
+	targetMod := filepath.Join(dir, "go.mod")
+	targetInfo, err := os.Stat(targetMod)
+	if err != nil {
+		return false, fmt.Errorf("stating %s: %w", targetMod, err)
+	}
+
+	return targetInfo.ModTime().After(binInfo.ModTime()), nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+func TestResolvedVersion(t *testing.T) {
+	gomod := &modfile.File{
+		Replace: []*modfile.Replace{
+			{
+				Old: module.Version{Path: "example.com/foo"},
+				New: module.Version{Path: "../local-foo"},
+			},
+			{
+				Old: module.Version{Path: "example.com/bar", Version: "v1.0.0"},
+				New: module.Version{Path: "example.com/bar", Version: "v1.0.1"},
+			},
+		},
+	}
+
+	t.Run("wildcard replace to a local path", func(t *testing.T) {
+		v, r := resolvedVersion(gomod, module.Version{Path: "example.com/foo", Version: "v1.2.3"})
+		if r == nil {
+			t.Fatalf("resolvedVersion() local replace = nil, want non-nil")
+		}
+		if v.Path != "../local-foo" {
+			t.Fatalf("resolvedVersion() = %+v, want path ../local-foo", v)
+		}
+	})
+
+	t.Run("versioned replace to another module+version", func(t *testing.T) {
+		v, r := resolvedVersion(gomod, module.Version{Path: "example.com/bar", Version: "v1.0.0"})
+		if r != nil {
+			t.Fatalf("resolvedVersion() local replace = %+v, want nil", r)
+		}
+		if v.Version != "v1.0.1" {
+			t.Fatalf("resolvedVersion() = %+v, want version v1.0.1", v)
+		}
+	})
+
+	t.Run("no applicable replace", func(t *testing.T) {
+		mod := module.Version{Path: "example.com/baz", Version: "v2.0.0"}
+		v, r := resolvedVersion(gomod, mod)
+		if r != nil {
+			t.Fatalf("resolvedVersion() local replace = %+v, want nil", r)
+		}
+		if v != mod {
+			t.Fatalf("resolvedVersion() = %+v, want unchanged %+v", v, mod)
+		}
+	})
+}
+
+func TestReplacementForPrefersExactVersionOverWildcard(t *testing.T) {
+	gomod := &modfile.File{
+		Replace: []*modfile.Replace{
+			{
+				Old: module.Version{Path: "example.com/foo"},
+				New: module.Version{Path: "example.com/foo", Version: "v1.0.0-wildcard"},
+			},
+			{
+				Old: module.Version{Path: "example.com/foo", Version: "v1.2.3"},
+				New: module.Version{Path: "example.com/foo", Version: "v1.0.0-exact"},
+			},
+		},
+	}
+
+	r := replacementFor(gomod, "example.com/foo", "v1.2.3")
+	if r == nil || r.New.Version != "v1.0.0-exact" {
+		t.Fatalf("replacementFor() = %+v, want the exact-version replace", r)
+	}
+
+	r = replacementFor(gomod, "example.com/foo", "v9.9.9")
+	if r == nil || r.New.Version != "v1.0.0-wildcard" {
+		t.Fatalf("replacementFor() = %+v, want the wildcard replace for a non-matching version", r)
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	gomod := &modfile.File{
+		Exclude: []*modfile.Exclude{
+			{Mod: module.Version{Path: "example.com/foo", Version: "v1.2.3"}},
+		},
+	}
+
+	if !excluded(gomod, module.Version{Path: "example.com/foo", Version: "v1.2.3"}) {
+		t.Fatalf("excluded() = false, want true for an excluded module+version")
+	}
+	if excluded(gomod, module.Version{Path: "example.com/foo", Version: "v1.2.4"}) {
+		t.Fatalf("excluded() = true, want false for a different version of the same module")
+	}
+}
+
+func TestLocalReplaceStale(t *testing.T) {
+	binDir := t.TempDir()
+	binPath := filepath.Join(binDir, "bin")
+	if err := os.WriteFile(binPath, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	targetDir := t.TempDir()
+	targetGoMod := filepath.Join(targetDir, "go.mod")
+	if err := os.WriteFile(targetGoMod, []byte("module example.com/local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	r := &modfile.Replace{New: module.Version{Path: targetDir}}
+
+	t.Run("target older than binary", func(t *testing.T) {
+		if err := os.Chtimes(targetGoMod, now, now.Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(binPath, now, now); err != nil {
+			t.Fatal(err)
+		}
+
+		stale, err := localReplaceStale(r, ".", binPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stale {
+			t.Fatalf("localReplaceStale() = true, want false")
+		}
+	})
+
+	t.Run("target newer than binary", func(t *testing.T) {
+		if err := os.Chtimes(binPath, now, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(targetGoMod, now, now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		stale, err := localReplaceStale(r, ".", binPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !stale {
+			t.Fatalf("localReplaceStale() = false, want true")
+		}
+	})
+}
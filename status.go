@@ -0,0 +1,277 @@
+package main
+
+import (
+	"debug/buildinfo"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
+)
+
+// Status values reported for a checked binary. These are part of the
+// --format=json contract, so keep them stable.
+const (
+	statusUpToDate     = "up-to-date"
+	statusMissing      = "missing"
+	statusStaleMod     = "stale-module"
+	statusStaleGo      = "stale-go"
+	statusStaleSetting = "stale-settings"
+	statusPathChange   = "path-changed"
+	statusError        = "error"
+)
+
+// binaryStatus is the result of checking a single binary against go.mod. It
+// is also the --format=json record shape.
+type binaryStatus struct {
+	Path             string `json:"path"`
+	Module           string `json:"module,omitempty"`
+	InstalledVersion string `json:"installed_version,omitempty"`
+	RequiredVersion  string `json:"required_version,omitempty"`
+	InstalledGo      string `json:"installed_go,omitempty"`
+	RequiredGo       string `json:"required_go,omitempty"`
+	Status           string `json:"status"`
+	Detail           string `json:"detail,omitempty"`
+	Error            string `json:"error,omitempty"`
+	UpgradeAvailable *bool  `json:"upgrade_available,omitempty"`
+	LatestVersion    string `json:"latest_version,omitempty"`
+
+	err error
+}
+
+// checkOptions collects the knobs that affect how a binary is checked,
+// beyond its path.
+type checkOptions struct {
+	toolsModPath  string
+	format        string
+	checkUpgrades bool
+
+	requirementsPath string
+	requireTags      []string
+	requireTrimpath  bool
+	requireGOOS      string
+	requireGOARCH    string
+}
+
+// runCheck checks every binary in paths and reports the results in the
+// requested format. It returns an *exitCodeError carrying the exit code
+// described in the root command's long help.
+func runCheck(cmd *cobra.Command, paths []string, opts checkOptions) error {
+	if opts.format != "text" && opts.format != "json" {
+		return fmt.Errorf("unknown --format %q, must be \"text\" or \"json\"", opts.format)
+	}
+
+	statuses := make([]binaryStatus, 0, len(paths))
+	for _, p := range paths {
+		statuses = append(statuses, checkBinary(p, opts))
+	}
+
+	if err := printStatuses(cmd, statuses, opts.format); err != nil {
+		return err
+	}
+
+	return exitCodeFor(statuses)
+}
+
+// exitCodeFor selects the *exitCodeError to return for a completed run of
+// statuses, taking the first that applies in the order error (1), stale (2),
+// then upgrade-available (3), or nil if every binary is up-to-date with no
+// upgrade available.
+func exitCodeFor(statuses []binaryStatus) error {
+	var anyError, anyStale, anyUpgrade bool
+	for _, s := range statuses {
+		switch s.Status {
+		case statusError:
+			anyError = true
+		case statusUpToDate:
+		default:
+			anyStale = true
+		}
+
+		if s.UpgradeAvailable != nil && *s.UpgradeAvailable {
+			anyUpgrade = true
+		}
+	}
+
+	if anyError {
+		return &exitCodeError{code: 1, err: fmt.Errorf("one or more binaries could not be checked")}
+	}
+	if anyStale {
+		return &exitCodeError{code: 2, err: fmt.Errorf("one or more binaries require install")}
+	}
+	if anyUpgrade {
+		return &exitCodeError{code: 3, err: fmt.Errorf("one or more binaries have a newer version available")}
+	}
+
+	return nil
+}
+
+func printStatuses(cmd *cobra.Command, statuses []binaryStatus, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		for _, s := range statuses {
+			if err := enc.Encode(s); err != nil {
+				return fmt.Errorf("encoding status for %s: %w", s.Path, err)
+			}
+		}
+
+		return nil
+	}
+
+	for _, s := range statuses {
+		switch s.Status {
+		case statusUpToDate:
+			if s.UpgradeAvailable != nil && *s.UpgradeAvailable {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: upgrade available: %s -> %s\n", s.Path, s.InstalledVersion, s.LatestVersion)
+			}
+		case statusError:
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: error: %s\n", s.Path, s.Error)
+		case statusStaleSetting:
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s: %s\n", s.Path, s.Status, s.Detail)
+		default:
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", s.Path, s.Status)
+		}
+	}
+
+	return nil
+}
+
+// checkBinary compares the binary at binPath against the pinned version in
+// go.mod (and, if toolsModPath is set, a secondary tools modfile), returning
+// its binaryStatus. It never returns an error; a failure to check is itself
+// reported as a statusError record.
+func checkBinary(binPath string, opts checkOptions) binaryStatus {
+	status := binaryStatus{Path: binPath}
+
+	gomod, err := readModFile("go.mod")
+	if err != nil {
+		return errStatus(status, err)
+	}
+
+	status.RequiredGo = "go" + gomod.Go.Version
+
+	info, err := buildinfo.ReadFile(binPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			status.Status = statusMissing
+			return status
+		}
+
+		return errStatus(status, fmt.Errorf("reading binary buildinfo (%s): %w", binPath, err))
+	}
+
+	status.InstalledGo = info.GoVersion
+	status.Module = info.Main.Path
+	status.InstalledVersion = info.Main.Version
+
+	goUpdate, err := needsUpdateForGo(gomod, info)
+	if err != nil {
+		return errStatus(status, err)
+	}
+	if goUpdate {
+		status.Status = statusStaleGo
+		return status
+	}
+
+	// Track which modfile (primary or tools) produced the match, and its
+	// directory, so a local "replace" target resolves relative to the right
+	// go.mod.
+	sourceGomod, sourceDir := gomod, "."
+	mod := versionFromGoMod(gomod, info.Main, info.Path)
+	if mod == nil && opts.toolsModPath != "" {
+		toolsGomod, err := readModFile(opts.toolsModPath)
+		if err != nil {
+			return errStatus(status, fmt.Errorf("reading tools modfile: %w", err))
+		}
+
+		if m := versionFromGoMod(toolsGomod, info.Main, info.Path); m != nil {
+			mod, sourceGomod, sourceDir = m, toolsGomod, filepath.Dir(opts.toolsModPath)
+		}
+	}
+	// We didn't find a match between the modfile(s) and the binary. Can
+	// happen if a binary has changed import paths.
+	if mod == nil {
+		status.Status = statusPathChange
+		return status
+	}
+
+	if excluded(sourceGomod, module.Version{Path: info.Main.Path, Version: info.Main.Version}) {
+		status.Status = statusStaleMod
+		status.Detail = fmt.Sprintf("%s@%s is excluded in go.mod", info.Main.Path, info.Main.Version)
+		return status
+	}
+
+	effective, localReplace := resolvedVersion(sourceGomod, *mod)
+	if localReplace != nil {
+		status.RequiredVersion = "replace => " + localReplace.New.Path
+
+		stale, err := localReplaceStale(localReplace, sourceDir, binPath)
+		if err != nil {
+			return errStatus(status, err)
+		}
+		if stale {
+			status.Status = statusStaleMod
+			status.Detail = fmt.Sprintf("local replace target %s has a newer go.mod than the binary", localReplace.New.Path)
+			return status
+		}
+	} else {
+		status.RequiredVersion = effective.Version
+		if info.Main.Version != effective.Version {
+			status.Status = statusStaleMod
+			return status
+		}
+	}
+
+	if replacedLocally(sourceGomod, info.Main.Path) && vcsModified(info) {
+		status.Status = statusStaleSetting
+		status.Detail = "local replace target has uncommitted changes (vcs.modified=true)"
+		return status
+	}
+
+	req, err := loadBuildRequirements(opts.requirementsPath)
+	if err != nil {
+		return errStatus(status, err)
+	}
+	req.Tags = append(req.Tags, opts.requireTags...)
+	if opts.requireTrimpath {
+		req.Trimpath = true
+	}
+	if opts.requireGOOS != "" {
+		req.GOOS = opts.requireGOOS
+	}
+	if opts.requireGOARCH != "" {
+		req.GOARCH = opts.requireGOARCH
+	}
+
+	if mismatch := settingsMismatch(info, req); mismatch != "" {
+		status.Status = statusStaleSetting
+		status.Detail = mismatch
+		return status
+	}
+
+	status.Status = statusUpToDate
+
+	if opts.checkUpgrades {
+		// Best-effort: a proxy query failure shouldn't turn an otherwise
+		// up-to-date binary into an error.
+		if available, latest, err := checkUpgrade(info.Main.Path, info.Main.Version); err == nil {
+			status.UpgradeAvailable = &available
+			if available {
+				status.LatestVersion = latest
+			}
+		}
+	}
+
+	return status
+}
+
+func errStatus(status binaryStatus, err error) binaryStatus {
+	status.Status = statusError
+	status.Error = err.Error()
+	status.err = err
+
+	return status
+}
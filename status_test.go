@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	upgradeTrue := true
+
+	tests := []struct {
+		name     string
+		statuses []binaryStatus
+		wantCode int // 0 means exitCodeFor should return nil
+	}{
+		{
+			name:     "all up to date",
+			statuses: []binaryStatus{{Status: statusUpToDate}},
+			wantCode: 0,
+		},
+		{
+			name: "error takes priority over stale and upgrade",
+			statuses: []binaryStatus{
+				{Status: statusError},
+				{Status: statusStaleMod},
+				{Status: statusUpToDate, UpgradeAvailable: &upgradeTrue},
+			},
+			wantCode: 1,
+		},
+		{
+			name: "stale takes priority over upgrade",
+			statuses: []binaryStatus{
+				{Status: statusStaleMod},
+				{Status: statusUpToDate, UpgradeAvailable: &upgradeTrue},
+			},
+			wantCode: 2,
+		},
+		{
+			name:     "upgrade available alone",
+			statuses: []binaryStatus{{Status: statusUpToDate, UpgradeAvailable: &upgradeTrue}},
+			wantCode: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exitCodeFor(tt.statuses)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("exitCodeFor() = %v, want nil", err)
+				}
+				return
+			}
+
+			var exitErr *exitCodeError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("exitCodeFor() = %v, want *exitCodeError", err)
+			}
+			if exitErr.code != tt.wantCode {
+				t.Fatalf("exitCodeFor() code = %d, want %d", exitErr.code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPrintStatusesJSON(t *testing.T) {
+	statuses := []binaryStatus{
+		{Path: "bin/foo", Status: statusUpToDate, Module: "example.com/foo", InstalledVersion: "v1.0.0"},
+		{Path: "bin/bar", Status: statusStaleMod},
+	}
+
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	if err := printStatuses(cmd, statuses, "json"); err != nil {
+		t.Fatalf("printStatuses() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{`"path":"bin/foo"`, `"status":"up-to-date"`, `"path":"bin/bar"`, `"status":"stale-module"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("printStatuses() json output %q missing %q", out, want)
+		}
+	}
+}
+
+func TestPrintStatusesText(t *testing.T) {
+	upgradeTrue := true
+	statuses := []binaryStatus{
+		{Path: "bin/foo", Status: statusUpToDate, UpgradeAvailable: &upgradeTrue, InstalledVersion: "v1.0.0", LatestVersion: "v1.1.0"},
+		{Path: "bin/bar", Status: statusStaleSetting, Detail: "built without -trimpath"},
+		{Path: "bin/baz", Status: statusError, Error: "boom"},
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+
+	if err := printStatuses(cmd, statuses, "text"); err != nil {
+		t.Fatalf("printStatuses() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "bin/foo: upgrade available: v1.0.0 -> v1.1.0") {
+		t.Fatalf("printStatuses() stdout = %q, missing upgrade line", out.String())
+	}
+	if !strings.Contains(out.String(), "bin/bar: stale-settings: built without -trimpath") {
+		t.Fatalf("printStatuses() stdout = %q, missing stale-settings detail", out.String())
+	}
+	if !strings.Contains(errOut.String(), "bin/baz: error: boom") {
+		t.Fatalf("printStatuses() stderr = %q, missing error line", errOut.String())
+	}
+}